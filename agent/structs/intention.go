@@ -4,9 +4,11 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/consul/acl"
@@ -53,6 +55,11 @@ type Intention struct {
 	// SourceType is the type of the value for the source.
 	SourceType IntentionSourceType
 
+	// SourceJWT identifies the JWT provider and required claims for the
+	// source when SourceType is IntentionSourceJWT. It is mutually
+	// exclusive with SourceNS/SourceName, which identify a Consul source.
+	SourceJWT *IntentionJWTSource `json:",omitempty"`
+
 	// Action is whether this is an allowlist or denylist intention.
 	Action IntentionAction
 
@@ -67,6 +74,28 @@ type Intention struct {
 	// opaque to Consul but is served in API responses.
 	Meta map[string]string
 
+	// Permissions is an optional list of L7 rules evaluated in order
+	// against the method, path and headers of a request. A permission whose matcher matches
+	// the candidate request takes precedence over the top-level Action.
+	// When empty, the intention behaves as a pure L4 allow/deny rule.
+	Permissions []*IntentionPermission `bexpr:"-"`
+
+	// NotBefore, if set, is the earliest time at which this intention is
+	// active. Before this time the intention is ignored during matching,
+	// as if it didn't exist.
+	NotBefore time.Time `json:",omitempty"`
+
+	// NotAfter, if set, is the latest time at which this intention is
+	// active. After this time the intention is ignored during matching,
+	// as if it didn't exist.
+	NotAfter time.Time `json:",omitempty"`
+
+	// Schedule, if set, additionally restricts this intention to the
+	// recurring windows it describes. NotBefore/NotAfter and Schedule
+	// compose: the intention is only active when every bound that is set
+	// agrees.
+	Schedule *IntentionSchedule `json:",omitempty"`
+
 	// Precedence is the order that the intention will be applied, with
 	// larger numbers being applied first. This is a read-only field, on
 	// any intention update it is updated.
@@ -94,10 +123,39 @@ func (t *Intention) Clone() *Intention {
 			t2.Meta[k] = v
 		}
 	}
+	if t.Permissions != nil {
+		t2.Permissions = make([]*IntentionPermission, len(t.Permissions))
+		for i, p := range t.Permissions {
+			t2.Permissions[i] = p.Clone()
+		}
+	}
+	if t.SourceJWT != nil {
+		t2.SourceJWT = t.SourceJWT.Clone()
+	}
+	if t.Schedule != nil {
+		t2.Schedule = t.Schedule.Clone()
+	}
 	t2.Hash = nil
 	return &t2
 }
 
+// ActiveAt returns true if this intention should be considered during
+// matching at time t, based on its NotBefore/NotAfter bounds and its
+// recurring Schedule, if any. An intention with none of these set is
+// always active.
+func (x *Intention) ActiveAt(t time.Time) bool {
+	if !x.NotBefore.IsZero() && t.Before(x.NotBefore) {
+		return false
+	}
+	if !x.NotAfter.IsZero() && t.After(x.NotAfter) {
+		return false
+	}
+	if x.Schedule != nil && !x.Schedule.ActiveAt(t) {
+		return false
+	}
+	return true
+}
+
 func (t *Intention) UnmarshalJSON(data []byte) (err error) {
 	type Alias Intention
 	aux := &struct {
@@ -139,12 +197,41 @@ func (x *Intention) SetHash() {
 	hash.Write([]byte(x.DestinationName))
 	hash.Write([]byte(x.SourceType))
 	hash.Write([]byte(x.Action))
+	if x.SourceJWT != nil {
+		hash.Write([]byte(x.SourceJWT.Provider))
+		hash.Write([]byte(x.SourceJWT.Issuer))
+		hash.Write([]byte(x.SourceJWT.SubjectRegex))
+		for _, aud := range x.SourceJWT.Audiences {
+			hash.Write([]byte(aud))
+		}
+		for _, c := range x.SourceJWT.Claims {
+			hash.Write([]byte(strings.Join(c.Path, ".")))
+			hash.Write([]byte(c.Value))
+		}
+	}
 	// hash.Write can not return an error, so the only way for binary.Write to
 	// error is to pass it data with an invalid data type. Doing so would be a
 	// programming error, so panic in that case.
 	if err := binary.Write(hash, binary.LittleEndian, uint64(x.Precedence)); err != nil {
 		panic(err)
 	}
+	if !x.NotBefore.IsZero() {
+		if err := binary.Write(hash, binary.LittleEndian, x.NotBefore.UnixNano()); err != nil {
+			panic(err)
+		}
+	}
+	if !x.NotAfter.IsZero() {
+		if err := binary.Write(hash, binary.LittleEndian, x.NotAfter.UnixNano()); err != nil {
+			panic(err)
+		}
+	}
+	if x.Schedule != nil {
+		hash.Write([]byte(x.Schedule.Cron))
+		hash.Write([]byte(x.Schedule.TimeZone))
+		if err := binary.Write(hash, binary.LittleEndian, uint64(x.Schedule.DurationMinutes)); err != nil {
+			panic(err)
+		}
+	}
 
 	// sort keys to ensure hash stability when meta is stored later
 	var keys []string
@@ -158,6 +245,33 @@ func (x *Intention) SetHash() {
 		hash.Write([]byte(x.Meta[k]))
 	}
 
+	// Permissions are order-sensitive (they're evaluated in order), so they
+	// are hashed in the order given rather than sorted first.
+	for _, p := range x.Permissions {
+		hash.Write([]byte(p.Action))
+		if p.HTTP != nil {
+			hash.Write([]byte(p.HTTP.PathExact))
+			hash.Write([]byte(p.HTTP.PathPrefix))
+			hash.Write([]byte(p.HTTP.PathRegex))
+			for _, m := range p.HTTP.Methods {
+				hash.Write([]byte(m))
+			}
+			for _, hdr := range p.HTTP.Header {
+				hash.Write([]byte(hdr.Name))
+				hash.Write([]byte(hdr.Exact))
+				hash.Write([]byte(hdr.Prefix))
+				hash.Write([]byte(hdr.Suffix))
+				hash.Write([]byte(hdr.Regex))
+				if hdr.Present {
+					hash.Write([]byte{1})
+				}
+				if hdr.Invert {
+					hash.Write([]byte{1})
+				}
+			}
+		}
+	}
+
 	x.Hash = hash.Sum(nil)
 }
 
@@ -167,11 +281,17 @@ func (x *Intention) Validate() error {
 	var result error
 
 	// Empty values
-	if x.SourceNS == "" {
-		result = multierror.Append(result, fmt.Errorf("SourceNS must be set"))
-	}
-	if x.SourceName == "" {
-		result = multierror.Append(result, fmt.Errorf("SourceName must be set"))
+	//
+	// SourceNS and SourceName identify a Consul-registered source and so
+	// only apply when SourceType is IntentionSourceConsul; a JWT-sourced
+	// intention has no consul source identity and is validated below.
+	if x.SourceType != IntentionSourceJWT {
+		if x.SourceNS == "" {
+			result = multierror.Append(result, fmt.Errorf("SourceNS must be set"))
+		}
+		if x.SourceName == "" {
+			result = multierror.Append(result, fmt.Errorf("SourceName must be set"))
+		}
 	}
 	if x.DestinationNS == "" {
 		result = multierror.Append(result, fmt.Errorf("DestinationNS must be set"))
@@ -245,9 +365,40 @@ func (x *Intention) Validate() error {
 
 	switch x.SourceType {
 	case IntentionSourceConsul:
+		if x.SourceJWT != nil {
+			result = multierror.Append(result, fmt.Errorf(
+				"SourceJWT cannot be set when SourceType is 'consul'"))
+		}
+	case IntentionSourceJWT:
+		if x.SourceNS != "" || x.SourceName != "" {
+			result = multierror.Append(result, fmt.Errorf(
+				"SourceNS and SourceName cannot be set when SourceType is 'jwt'"))
+		}
+		if x.SourceJWT == nil {
+			result = multierror.Append(result, fmt.Errorf(
+				"SourceJWT must be set when SourceType is 'jwt'"))
+		} else if err := x.SourceJWT.Validate(); err != nil {
+			result = multierror.Append(result, err)
+		}
 	default:
 		result = multierror.Append(result, fmt.Errorf(
-			"SourceType must be set to 'consul'"))
+			"SourceType must be set to 'consul' or 'jwt'"))
+	}
+
+	for i, p := range x.Permissions {
+		if err := p.Validate(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("Permissions[%d]: %w", i, err))
+		}
+	}
+
+	if !x.NotBefore.IsZero() && !x.NotAfter.IsZero() && x.NotAfter.Before(x.NotBefore) {
+		result = multierror.Append(result, fmt.Errorf("NotAfter must not be before NotBefore"))
+	}
+
+	if x.Schedule != nil {
+		if err := x.Schedule.Validate(); err != nil {
+			result = multierror.Append(result, err)
+		}
 	}
 
 	return result
@@ -314,11 +465,38 @@ func (x *Intention) UpdatePrecedence() {
 	}
 
 	// Given the maximum, the exact value is determined based on the
-	// number of source exact values.
-	countSrc := x.countExact(x.SourceNS, x.SourceName)
+	// specificity of the source.
+	countSrc := x.sourcePrecedenceExactness()
 	x.Precedence = max - (2 - countSrc)
 }
 
+// sourcePrecedenceExactness scores how narrowly this intention's source
+// pins down a caller, on the same 0-2 scale as countExact.
+//
+// A Consul source is scored by countExact on SourceNS/SourceName as
+// before. A JWT source has no namespace/name to count - SourceNS and
+// SourceName are always "" per Validate - so it's scored on how much of
+// SourceJWT narrows the caller instead: a bare Provider is as general as
+// a fully wildcarded Consul source (0); adding Issuer or Audiences
+// narrows it (1); a SubjectRegex or Claims assertion narrows it to
+// specific callers (2).
+func (x *Intention) sourcePrecedenceExactness() int {
+	if x.SourceType != IntentionSourceJWT {
+		return x.countExact(x.SourceNS, x.SourceName)
+	}
+
+	if x.SourceJWT == nil {
+		return 0
+	}
+	if x.SourceJWT.SubjectRegex != "" || len(x.SourceJWT.Claims) > 0 {
+		return 2
+	}
+	if x.SourceJWT.Issuer != "" || len(x.SourceJWT.Audiences) > 0 {
+		return 1
+	}
+	return 0
+}
+
 // countExact counts the number of exact values (not wildcards) in
 // the given namespace and name.
 func (x *Intention) countExact(ns, n string) int {
@@ -356,6 +534,42 @@ func (x *Intention) EstimateSize() int {
 		size += len(k) + len(v)
 	}
 
+	if !x.NotBefore.IsZero() {
+		size += 8
+	}
+	if !x.NotAfter.IsZero() {
+		size += 8
+	}
+	if x.Schedule != nil {
+		size += len(x.Schedule.Cron) + len(x.Schedule.TimeZone) + 8
+	}
+
+	if x.SourceJWT != nil {
+		size += len(x.SourceJWT.Provider) + len(x.SourceJWT.Issuer) + len(x.SourceJWT.SubjectRegex)
+		for _, aud := range x.SourceJWT.Audiences {
+			size += len(aud)
+		}
+		for _, c := range x.SourceJWT.Claims {
+			for _, part := range c.Path {
+				size += len(part)
+			}
+			size += len(c.Value)
+		}
+	}
+
+	for _, p := range x.Permissions {
+		size += len(p.Action)
+		if p.HTTP != nil {
+			size += len(p.HTTP.PathExact) + len(p.HTTP.PathPrefix) + len(p.HTTP.PathRegex)
+			for _, m := range p.HTTP.Methods {
+				size += len(m)
+			}
+			for _, hdr := range p.HTTP.Header {
+				size += len(hdr.Name) + len(hdr.Exact) + len(hdr.Prefix) + len(hdr.Suffix) + len(hdr.Regex)
+			}
+		}
+	}
+
 	return size
 }
 
@@ -374,8 +588,724 @@ type IntentionSourceType string
 const (
 	// IntentionSourceConsul is a service within the Consul catalog.
 	IntentionSourceConsul IntentionSourceType = "consul"
+
+	// IntentionSourceJWT identifies a source by the claims of a verified
+	// JWT rather than a Consul service identity.
+	IntentionSourceJWT IntentionSourceType = "jwt"
 )
 
+// IntentionJWTSource names the JWT provider and the required claims for a
+// JWT-sourced intention (SourceType IntentionSourceJWT). A request is
+// considered to come from this source if its JWT was verified by Provider
+// and all of the configured claim criteria hold.
+type IntentionJWTSource struct {
+	// Provider is the name of the JWT provider, configured elsewhere,
+	// that verifies tokens for this source.
+	Provider string
+
+	// Issuer, if set, requires the token's "iss" claim to match exactly.
+	Issuer string `json:",omitempty"`
+
+	// Audiences, if set, requires the token's "aud" claim to contain at
+	// least one of the listed values.
+	Audiences []string `json:",omitempty"`
+
+	// SubjectRegex, if set, requires the token's "sub" claim to match
+	// this regular expression.
+	SubjectRegex string `json:",omitempty"`
+
+	// Claims is a list of additional claim=value assertions that must
+	// all hold for the token to match this source.
+	Claims []IntentionJWTClaimAssertion `json:",omitempty"`
+}
+
+// Clone returns a deep copy of j.
+func (j *IntentionJWTSource) Clone() *IntentionJWTSource {
+	j2 := *j
+	if j.Audiences != nil {
+		j2.Audiences = make([]string, len(j.Audiences))
+		copy(j2.Audiences, j.Audiences)
+	}
+	if j.Claims != nil {
+		j2.Claims = make([]IntentionJWTClaimAssertion, len(j.Claims))
+		for i, c := range j.Claims {
+			j2.Claims[i] = c.Clone()
+		}
+	}
+	return &j2
+}
+
+// Validate returns an error if the JWT source is invalid.
+func (j *IntentionJWTSource) Validate() error {
+	var result error
+
+	if j.Provider == "" {
+		result = multierror.Append(result, fmt.Errorf("SourceJWT.Provider must be set"))
+	}
+
+	if j.SubjectRegex != "" {
+		if _, err := regexp.Compile(j.SubjectRegex); err != nil {
+			result = multierror.Append(result, fmt.Errorf(
+				"SourceJWT.SubjectRegex is invalid: %w", err))
+		}
+	}
+
+	for i, c := range j.Claims {
+		if err := c.Validate(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("SourceJWT.Claims[%d]: %w", i, err))
+		}
+	}
+
+	return result
+}
+
+// Matches returns true if the decoded claims satisfy this JWT source,
+// i.e. whether a request bearing a token with these claims would be
+// considered to originate from this source.
+func (j *IntentionJWTSource) Matches(claims *IntentionJWTClaims) bool {
+	if claims == nil {
+		return false
+	}
+
+	if j.Issuer != "" && claims.Issuer != j.Issuer {
+		return false
+	}
+
+	if len(j.Audiences) > 0 {
+		found := false
+		for _, want := range j.Audiences {
+			for _, got := range claims.Audience {
+				if want == got {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if j.SubjectRegex != "" {
+		re, err := regexp.Compile(j.SubjectRegex)
+		if err != nil {
+			return false
+		}
+		if !re.MatchString(claims.Subject) {
+			return false
+		}
+	}
+
+	for _, c := range j.Claims {
+		if !c.matches(claims.Claims) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IntentionJWTClaimAssertion asserts that the claim found by walking Path
+// through the decoded JWT claims equals Value.
+type IntentionJWTClaimAssertion struct {
+	// Path is the path to the claim, e.g. ["roles"] or ["nested", "org"]
+	// for a claim nested under another object.
+	Path []string
+
+	// Value is the expected value of the claim, compared as a string.
+	Value string
+}
+
+// Clone returns a deep copy of c.
+func (c IntentionJWTClaimAssertion) Clone() IntentionJWTClaimAssertion {
+	c2 := c
+	if c.Path != nil {
+		c2.Path = make([]string, len(c.Path))
+		copy(c2.Path, c.Path)
+	}
+	return c2
+}
+
+// Validate returns an error if the claim assertion is invalid.
+func (c IntentionJWTClaimAssertion) Validate() error {
+	if len(c.Path) == 0 {
+		return fmt.Errorf("Path must be set")
+	}
+	if c.Value == "" {
+		return fmt.Errorf("Value must be set")
+	}
+	return nil
+}
+
+// matches walks claims along c.Path and returns true if the resulting
+// value equals c.Value.
+func (c IntentionJWTClaimAssertion) matches(claims map[string]interface{}) bool {
+	var cur interface{} = claims
+	for _, part := range c.Path {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		cur, ok = m[part]
+		if !ok {
+			return false
+		}
+	}
+
+	switch v := cur.(type) {
+	case string:
+		return v == c.Value
+	case fmt.Stringer:
+		return v.String() == c.Value
+	default:
+		return fmt.Sprintf("%v", v) == c.Value
+	}
+}
+
+// IntentionJWTClaims is a decoded set of JWT claims, provided by the
+// caller of IntentionQueryCheck, used to evaluate whether a JWT-sourced
+// intention would allow the token that produced them.
+type IntentionJWTClaims struct {
+	Issuer   string
+	Audience []string
+	Subject  string
+
+	// Claims holds the full set of decoded claims, keyed by claim name,
+	// for evaluating IntentionJWTSource.Claims assertions.
+	Claims map[string]interface{}
+}
+
+// IntentionSchedule describes a recurring time window, expressed as a
+// standard 5-field cron expression plus a duration, during which an
+// Intention is active.
+type IntentionSchedule struct {
+	// Cron is a standard 5-field cron expression ("minute hour
+	// day-of-month month day-of-week") identifying when each window
+	// begins. A minute begins a window only if it satisfies all five
+	// fields; day-of-month and day-of-week are not OR-ed when both are
+	// restricted. Day-of-week takes 0-6 (0 is Sunday); names such as
+	// "MON" are not accepted.
+	Cron string
+
+	// DurationMinutes is how long each window described by Cron stays
+	// active, in minutes. Must be greater than zero.
+	DurationMinutes int
+
+	// TimeZone is the IANA time zone name the schedule is evaluated in,
+	// e.g. "America/New_York". Defaults to UTC when empty.
+	TimeZone string `json:",omitempty"`
+}
+
+// Clone returns a deep copy of s.
+func (s *IntentionSchedule) Clone() *IntentionSchedule {
+	s2 := *s
+	return &s2
+}
+
+// Validate returns an error if the schedule is invalid.
+func (s *IntentionSchedule) Validate() error {
+	var result error
+
+	if s.DurationMinutes <= 0 {
+		result = multierror.Append(result, fmt.Errorf(
+			"Schedule.DurationMinutes must be greater than zero"))
+	} else if s.DurationMinutes > maxScheduleLookback {
+		result = multierror.Append(result, fmt.Errorf(
+			"Schedule.DurationMinutes must not exceed %d, got %d",
+			maxScheduleLookback, s.DurationMinutes))
+	}
+
+	if _, err := parseCronFields(s.Cron); err != nil {
+		result = multierror.Append(result, fmt.Errorf("Schedule.Cron is invalid: %w", err))
+	}
+
+	if s.TimeZone != "" {
+		if _, err := time.LoadLocation(s.TimeZone); err != nil {
+			result = multierror.Append(result, fmt.Errorf("Schedule.TimeZone is invalid: %w", err))
+		}
+	}
+
+	return result
+}
+
+// maxScheduleLookback is the maximum DurationMinutes Validate allows, and
+// therefore also how far ActiveAt ever needs to scan backward to find a
+// matching window start.
+const maxScheduleLookback = 14 * 24 * 60
+
+// ActiveAt returns true if t falls within one of the recurring windows
+// described by this schedule.
+func (s *IntentionSchedule) ActiveAt(t time.Time) bool {
+	loc := time.UTC
+	if s.TimeZone != "" {
+		if l, err := time.LoadLocation(s.TimeZone); err == nil {
+			loc = l
+		}
+	}
+	t = t.In(loc)
+
+	fields, err := parseCronFieldsCached(s.Cron)
+	if err != nil {
+		return false
+	}
+
+	dur := time.Duration(s.DurationMinutes) * time.Minute
+	if dur <= 0 {
+		return false
+	}
+
+	// Validate rejects DurationMinutes over maxScheduleLookback, but cap
+	// defensively here too in case this schedule predates that check.
+	limit := s.DurationMinutes
+	if limit > maxScheduleLookback {
+		limit = maxScheduleLookback
+	}
+
+	cur := t.Truncate(time.Minute)
+	for i := 0; i <= limit; i++ {
+		candidate := cur.Add(-time.Duration(i) * time.Minute)
+		if fields.matches(candidate) && t.Before(candidate.Add(dur)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cronFields is a parsed, evaluatable form of a 5-field cron expression.
+type cronFields struct {
+	minute, hour, dom, month, dow func(int) bool
+}
+
+func (c cronFields) matches(t time.Time) bool {
+	return c.minute(t.Minute()) && c.hour(t.Hour()) && c.dom(t.Day()) &&
+		c.month(int(t.Month())) && c.dow(int(t.Weekday()))
+}
+
+// cronFieldsCacheEntry is the result of parsing a single cron expression,
+// cached by expression string in cronFieldsCache.
+type cronFieldsCacheEntry struct {
+	fields cronFields
+	err    error
+}
+
+// cronFieldsCache memoizes parseCronFields by expression string.
+// ActiveAt is called during intention matching, which happens on the
+// request hot path, so repeatedly re-parsing the same handful of
+// Schedule.Cron expressions on every call would be wasted work.
+var cronFieldsCache sync.Map // map[string]cronFieldsCacheEntry
+
+// parseCronFieldsCached is parseCronFields, memoized by expression.
+func parseCronFieldsCached(expr string) (cronFields, error) {
+	if v, ok := cronFieldsCache.Load(expr); ok {
+		entry := v.(cronFieldsCacheEntry)
+		return entry.fields, entry.err
+	}
+
+	fields, err := parseCronFields(expr)
+	cronFieldsCache.Store(expr, cronFieldsCacheEntry{fields: fields, err: err})
+	return fields, err
+}
+
+// parseCronFields parses a standard 5-field cron expression: minute hour
+// day-of-month month day-of-week.
+func parseCronFields(expr string) (cronFields, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return cronFields{}, fmt.Errorf(
+			"cron expression must have 5 fields (minute hour dom month dow), got %d", len(fields))
+	}
+
+	minute, err := parseCronField(fields[0], 0, 59)
+	if err != nil {
+		return cronFields{}, fmt.Errorf("minute field: %w", err)
+	}
+	hour, err := parseCronField(fields[1], 0, 23)
+	if err != nil {
+		return cronFields{}, fmt.Errorf("hour field: %w", err)
+	}
+	dom, err := parseCronField(fields[2], 1, 31)
+	if err != nil {
+		return cronFields{}, fmt.Errorf("day-of-month field: %w", err)
+	}
+	month, err := parseCronField(fields[3], 1, 12)
+	if err != nil {
+		return cronFields{}, fmt.Errorf("month field: %w", err)
+	}
+	dow, err := parseCronField(fields[4], 0, 6)
+	if err != nil {
+		return cronFields{}, fmt.Errorf("day-of-week field: %w", err)
+	}
+
+	return cronFields{minute: minute, hour: hour, dom: dom, month: month, dow: dow}, nil
+}
+
+// parseCronField parses a single cron field, supporting "*", "*/step",
+// comma-separated lists, ranges ("a-b"), and stepped ranges ("a-b/step").
+func parseCronField(field string, min, max int) (func(int) bool, error) {
+	allowed := make(map[int]bool)
+
+	for _, part := range strings.Split(field, ",") {
+		rangeExpr, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			var err error
+			rangeExpr = part[:idx]
+			step, err = strconv.Atoi(part[idx+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		lo, hi := min, max
+		if rangeExpr != "*" {
+			if idx := strings.Index(rangeExpr, "-"); idx >= 0 {
+				var err error
+				lo, err = strconv.Atoi(rangeExpr[:idx])
+				if err != nil {
+					return nil, fmt.Errorf("invalid range start in %q", part)
+				}
+				hi, err = strconv.Atoi(rangeExpr[idx+1:])
+				if err != nil {
+					return nil, fmt.Errorf("invalid range end in %q", part)
+				}
+			} else {
+				v, err := strconv.Atoi(rangeExpr)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value %q", part)
+				}
+				lo, hi = v, v
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("value out of range in %q (expected %d-%d)", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			allowed[v] = true
+		}
+	}
+
+	return func(v int) bool { return allowed[v] }, nil
+}
+
+// IntentionPermission is a single L7 rule within an Intention's Permissions
+// list. Permissions are evaluated in order against a candidate request; the
+// Action of the first matching permission takes precedence over the
+// Intention's top-level Action.
+type IntentionPermission struct {
+	// Action is whether this permission is an allowlist or denylist rule.
+	Action IntentionAction
+
+	// HTTP is the L7 match criteria for this permission. A nil HTTP
+	// matches every request.
+	HTTP *IntentionHTTPPermission `json:",omitempty"`
+}
+
+// Clone returns a deep copy of p.
+func (p *IntentionPermission) Clone() *IntentionPermission {
+	p2 := *p
+	if p.HTTP != nil {
+		p2.HTTP = p.HTTP.Clone()
+	}
+	return &p2
+}
+
+// Validate returns an error if the permission is invalid.
+func (p *IntentionPermission) Validate() error {
+	var result error
+
+	switch p.Action {
+	case IntentionActionAllow, IntentionActionDeny:
+	default:
+		result = multierror.Append(result, fmt.Errorf(
+			"Action must be set to 'allow' or 'deny'"))
+	}
+
+	if p.HTTP != nil {
+		if err := p.HTTP.Validate(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result
+}
+
+// Matches returns true if req satisfies this permission's HTTP match
+// criteria. A permission with no HTTP matcher matches every request.
+func (p *IntentionPermission) Matches(req *IntentionHTTPRequest) bool {
+	if p.HTTP == nil {
+		return true
+	}
+	return p.HTTP.Matches(req)
+}
+
+// validIntentionHTTPMethods are the HTTP methods that may appear in
+// IntentionHTTPPermission.Methods.
+var validIntentionHTTPMethods = map[string]struct{}{
+	"GET":     {},
+	"HEAD":    {},
+	"POST":    {},
+	"PUT":     {},
+	"DELETE":  {},
+	"CONNECT": {},
+	"OPTIONS": {},
+	"TRACE":   {},
+	"PATCH":   {},
+}
+
+// IntentionHTTPPermission is the HTTP-specific match criteria for an
+// IntentionPermission. A gRPC call can also be matched using these fields
+// since gRPC requests are HTTP/2 requests whose path is always of the form
+// "/<package>.<Service>/<Method>" - use PathExact or PathPrefix to match a
+// specific gRPC service or method.
+type IntentionHTTPPermission struct {
+	// PathExact, PathPrefix, and PathRegex are mutually exclusive; at most
+	// one may be set. If none are set, the permission matches any path.
+	PathExact  string `json:",omitempty"`
+	PathPrefix string `json:",omitempty"`
+	PathRegex  string `json:",omitempty"`
+
+	// Header is a set of criteria to match on request headers. If more
+	// than one is configured, all of them must match for the permission
+	// to match.
+	Header []IntentionHTTPHeaderPermission `json:",omitempty"`
+
+	// Methods is the list of HTTP methods for which this permission
+	// applies. If empty, all HTTP methods are matched.
+	Methods []string `json:",omitempty"`
+}
+
+// Clone returns a deep copy of p.
+func (p *IntentionHTTPPermission) Clone() *IntentionHTTPPermission {
+	p2 := *p
+	if p.Header != nil {
+		p2.Header = make([]IntentionHTTPHeaderPermission, len(p.Header))
+		copy(p2.Header, p.Header)
+	}
+	if p.Methods != nil {
+		p2.Methods = make([]string, len(p.Methods))
+		copy(p2.Methods, p.Methods)
+	}
+	return &p2
+}
+
+// Validate returns an error if the HTTP permission is invalid.
+func (p *IntentionHTTPPermission) Validate() error {
+	var result error
+
+	pathParts := 0
+	if p.PathExact != "" {
+		pathParts++
+		if !strings.HasPrefix(p.PathExact, "/") {
+			result = multierror.Append(result, fmt.Errorf(
+				"PathExact must begin with a '/': %q", p.PathExact))
+		}
+	}
+	if p.PathPrefix != "" {
+		pathParts++
+		if !strings.HasPrefix(p.PathPrefix, "/") {
+			result = multierror.Append(result, fmt.Errorf(
+				"PathPrefix must begin with a '/': %q", p.PathPrefix))
+		}
+	}
+	if p.PathRegex != "" {
+		pathParts++
+		if _, err := regexp.Compile(p.PathRegex); err != nil {
+			result = multierror.Append(result, fmt.Errorf("PathRegex is invalid: %w", err))
+		}
+	}
+	if pathParts > 1 {
+		result = multierror.Append(result, fmt.Errorf(
+			"at most one of PathExact, PathPrefix, or PathRegex may be set"))
+	}
+
+	for _, hdr := range p.Header {
+		if err := hdr.Validate(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	for _, method := range p.Methods {
+		if _, ok := validIntentionHTTPMethods[method]; !ok {
+			result = multierror.Append(result, fmt.Errorf(
+				"Methods: %q is not a valid HTTP method", method))
+		}
+	}
+
+	return result
+}
+
+// Matches returns true if req satisfies this HTTP match criteria.
+func (p *IntentionHTTPPermission) Matches(req *IntentionHTTPRequest) bool {
+	if req == nil {
+		return true
+	}
+
+	if len(p.Methods) > 0 {
+		found := false
+		for _, m := range p.Methods {
+			if strings.EqualFold(m, req.Method) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	switch {
+	case p.PathExact != "":
+		if req.Path != p.PathExact {
+			return false
+		}
+	case p.PathPrefix != "":
+		if !strings.HasPrefix(req.Path, p.PathPrefix) {
+			return false
+		}
+	case p.PathRegex != "":
+		re, err := regexp.Compile(p.PathRegex)
+		if err != nil {
+			return false
+		}
+		if !re.MatchString(req.Path) {
+			return false
+		}
+	}
+
+	for _, hdr := range p.Header {
+		if !hdr.matches(req.Header) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IntentionHTTPHeaderPermission matches on the value of a single HTTP
+// request header.
+type IntentionHTTPHeaderPermission struct {
+	// Name is the name of the header to match.
+	Name string
+
+	// Present matches if the header is present with any value. Mutually
+	// exclusive with Exact, Prefix, Suffix, and Regex.
+	Present bool `json:",omitempty"`
+
+	// Exact, Prefix, Suffix, and Regex match on the header value and are
+	// mutually exclusive with each other and with Present.
+	Exact  string `json:",omitempty"`
+	Prefix string `json:",omitempty"`
+	Suffix string `json:",omitempty"`
+	Regex  string `json:",omitempty"`
+
+	// Invert inverts the result of the match.
+	Invert bool `json:",omitempty"`
+}
+
+// Validate returns an error if the header permission is invalid.
+func (hdr IntentionHTTPHeaderPermission) Validate() error {
+	if hdr.Name == "" {
+		return fmt.Errorf("Header: Name must be set")
+	}
+
+	set := 0
+	if hdr.Present {
+		set++
+	}
+	if hdr.Exact != "" {
+		set++
+	}
+	if hdr.Prefix != "" {
+		set++
+	}
+	if hdr.Suffix != "" {
+		set++
+	}
+	if hdr.Regex != "" {
+		set++
+		if _, err := regexp.Compile(hdr.Regex); err != nil {
+			return fmt.Errorf("Header[%q]: Regex is invalid: %w", hdr.Name, err)
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf(
+			"Header[%q]: exactly one of Present, Exact, Prefix, Suffix, or Regex must be set", hdr.Name)
+	}
+
+	return nil
+}
+
+// matches returns true if the given request headers satisfy hdr.
+func (hdr IntentionHTTPHeaderPermission) matches(header map[string][]string) bool {
+	values, ok := header[hdr.Name]
+	if !ok {
+		// Matched is uniformly false when the header is absent, regardless
+		// of which criterion is configured, so inversion applies uniformly
+		// too - including for Present, where Invert means "must be absent".
+		return hdr.Invert
+	}
+
+	var matched bool
+	switch {
+	case hdr.Present:
+		matched = true
+	case hdr.Exact != "":
+		for _, v := range values {
+			if v == hdr.Exact {
+				matched = true
+				break
+			}
+		}
+	case hdr.Prefix != "":
+		for _, v := range values {
+			if strings.HasPrefix(v, hdr.Prefix) {
+				matched = true
+				break
+			}
+		}
+	case hdr.Suffix != "":
+		for _, v := range values {
+			if strings.HasSuffix(v, hdr.Suffix) {
+				matched = true
+				break
+			}
+		}
+	case hdr.Regex != "":
+		re, err := regexp.Compile(hdr.Regex)
+		if err != nil {
+			return false
+		}
+		for _, v := range values {
+			if re.MatchString(v) {
+				matched = true
+				break
+			}
+		}
+	}
+
+	if hdr.Invert {
+		return !matched
+	}
+	return matched
+}
+
+// IntentionHTTPRequest is a candidate HTTP (or gRPC, see
+// IntentionHTTPPermission) request evaluated against an Intention's
+// Permissions, e.g. by IntentionQueryCheck.
+type IntentionHTTPRequest struct {
+	// Method is the HTTP method of the request, such as "GET".
+	Method string
+
+	// Path is the HTTP path of the request, including the leading "/".
+	Path string
+
+	// Header is the set of request headers, keyed by header name. A
+	// header permission looks up its Name in this map exactly as given,
+	// without case folding or canonicalization.
+	Header map[string][]string
+}
+
 // Intentions is a list of intentions.
 type Intentions []*Intention
 
@@ -514,6 +1444,16 @@ type IntentionQueryCheck struct {
 
 	// SourceType is the type of the value for the source.
 	SourceType IntentionSourceType
+
+	// HTTP is an optional candidate HTTP (or gRPC) request to evaluate
+	// against the Permissions of the matched intention. If unset, only
+	// the top-level L4 Action of the matched intention is considered.
+	HTTP *IntentionHTTPRequest `json:",omitempty"`
+
+	// JWT is an optional decoded claim set to evaluate against the
+	// SourceJWT of a JWT-sourced intention. It is ignored when matching
+	// against a Consul-sourced intention.
+	JWT *IntentionJWTClaims `json:",omitempty"`
 }
 
 // GetACLPrefix returns the prefix to look up the ACL policy for this
@@ -570,6 +1510,15 @@ func (s IntentionPrecedenceSorter) Less(i, j int) bool {
 		return a.Precedence > b.Precedence
 	}
 
+	// Among intentions with an otherwise identical 4-tuple precedence,
+	// prefer the one with more L7 Permissions, so that a rule scoped to
+	// particular HTTP endpoints is considered before a plain L4
+	// allow/deny covering the same source/destination pair. Only the
+	// number of Permissions is compared, not what they match.
+	if len(a.Permissions) != len(b.Permissions) {
+		return len(a.Permissions) > len(b.Permissions)
+	}
+
 	// Tie break on lexicographic order of the 4-tuple in canonical form (SrcNS,
 	// Src, DstNS, Dst). This is arbitrary but it keeps sorting deterministic
 	// which is a nice property for consistency. It is arguably open to abuse if
@@ -585,5 +1534,267 @@ func (s IntentionPrecedenceSorter) Less(i, j int) bool {
 	if a.DestinationNS != b.DestinationNS {
 		return a.DestinationNS < b.DestinationNS
 	}
-	return a.DestinationName < b.DestinationName
+	if a.DestinationName != b.DestinationName {
+		return a.DestinationName < b.DestinationName
+	}
+
+	// Final tie-break on the time window, so that ordering stays
+	// deterministic even when two intentions are otherwise identical and
+	// differ only by NotBefore/NotAfter/Schedule.
+	if !a.NotBefore.Equal(b.NotBefore) {
+		return a.NotBefore.Before(b.NotBefore)
+	}
+	if !a.NotAfter.Equal(b.NotAfter) {
+		return a.NotAfter.Before(b.NotAfter)
+	}
+	return scheduleSortKey(a.Schedule) < scheduleSortKey(b.Schedule)
+}
+
+// scheduleSortKey returns a string covering every field of s, or "" if s
+// is nil, for use as a sort key.
+func scheduleSortKey(s *IntentionSchedule) string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s\x00%s\x00%d", s.Cron, s.TimeZone, s.DurationMinutes)
+}
+
+// ConflictReason explains why two intentions were flagged by
+// ConflictsWith/DetectConflicts as being in conflict.
+type ConflictReason string
+
+const (
+	// ConflictReasonOverlappingAction means the two intentions have equal
+	// precedence, overlapping source/destination 4-tuples, and disagree
+	// on Action. IntentionPrecedenceSorter would pick a winner between
+	// them based on an arbitrary lexicographic tie-break, which is almost
+	// certainly not what was intended.
+	ConflictReasonOverlappingAction ConflictReason = "overlapping-action"
+
+	// ConflictReasonShadowed means the lower-precedence intention can
+	// never fire: a higher-precedence intention of the opposite Action
+	// fully covers its source/destination 4-tuple.
+	ConflictReasonShadowed ConflictReason = "shadowed"
+)
+
+// ConflictsWith reports whether x and other could both match the same
+// concrete source/destination pair yet disagree on whether to allow or
+// deny it. reason is the empty string when they don't conflict.
+//
+// Overlap is computed via the wildcard containment relation already
+// implied by countExact: on each of the four components (SourceNS,
+// SourceName, DestinationNS, DestinationName), either the two intentions
+// agree exactly, or at least one of them is a wildcard. Intentions with
+// different SourceTypes never overlap, and for JWT sources SourceJWT is
+// compared (see jwtSourcesOverlap) in place of SourceNS/SourceName.
+//
+// This only considers the static source/destination, Action, and
+// Precedence; it does not take NotBefore/NotAfter/Schedule or Permissions
+// into account, so two intentions whose active windows or L7 Permissions
+// never actually collide may still be reported as conflicting.
+func (x *Intention) ConflictsWith(other *Intention) (bool, ConflictReason) {
+	if x.Action == other.Action {
+		return false, ""
+	}
+	if !intentionComponentsOverlap(x, other) {
+		return false, ""
+	}
+
+	switch {
+	case x.Precedence == other.Precedence:
+		return true, ConflictReasonOverlappingAction
+	case x.Precedence > other.Precedence && intentionCovers(x, other):
+		return true, ConflictReasonShadowed
+	case other.Precedence > x.Precedence && intentionCovers(other, x):
+		return true, ConflictReasonShadowed
+	default:
+		return false, ""
+	}
+}
+
+// intentionComponentsOverlap returns true if a and b's source/destination
+// 4-tuples could both match the same concrete source/destination pair.
+func intentionComponentsOverlap(a, b *Intention) bool {
+	if a.SourceType != b.SourceType {
+		// A Consul-registered caller and a JWT bearer are established by
+		// disjoint mechanisms, so a source of one type never overlaps a
+		// source of the other.
+		return false
+	}
+
+	if a.SourceType == IntentionSourceJWT {
+		if !jwtSourcesOverlap(a.SourceJWT, b.SourceJWT) {
+			return false
+		}
+	} else if !componentsOverlap(a.SourceNS, b.SourceNS) || !componentsOverlap(a.SourceName, b.SourceName) {
+		return false
+	}
+
+	return componentsOverlap(a.DestinationNS, b.DestinationNS) &&
+		componentsOverlap(a.DestinationName, b.DestinationName)
+}
+
+// componentsOverlap returns true if a and b, each either an exact value
+// or the wildcard "*", could describe the same concrete value.
+func componentsOverlap(a, b string) bool {
+	return a == WildcardSpecifier || b == WildcardSpecifier || a == b
+}
+
+// jwtSourcesOverlap returns true if a and b could both be satisfied by a
+// token from the same real caller. Different providers are always
+// disjoint; differing Issuers or a contradictory claim assertion (same
+// path, different required value) rules out overlap too.
+func jwtSourcesOverlap(a, b *IntentionJWTSource) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Provider != b.Provider {
+		return false
+	}
+	if a.Issuer != "" && b.Issuer != "" && a.Issuer != b.Issuer {
+		return false
+	}
+	for _, ca := range a.Claims {
+		for _, cb := range b.Claims {
+			if equalClaimPaths(ca.Path, cb.Path) && ca.Value != cb.Value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// intentionCovers returns true if every concrete request matching
+// narrow's source/destination would also match broad's, i.e. broad is at
+// least as general as narrow and so fully shadows it.
+func intentionCovers(broad, narrow *Intention) bool {
+	if broad.SourceType != narrow.SourceType {
+		return false
+	}
+
+	if broad.SourceType == IntentionSourceJWT {
+		if !jwtSourceCovers(broad.SourceJWT, narrow.SourceJWT) {
+			return false
+		}
+	} else if !coversComponent(broad.SourceNS, narrow.SourceNS) || !coversComponent(broad.SourceName, narrow.SourceName) {
+		return false
+	}
+
+	return coversComponent(broad.DestinationNS, narrow.DestinationNS) &&
+		coversComponent(broad.DestinationName, narrow.DestinationName)
+}
+
+// coversComponent returns true if broad matches everything narrow does.
+func coversComponent(broad, narrow string) bool {
+	return broad == WildcardSpecifier || broad == narrow
+}
+
+// jwtSourceCovers returns true if every caller matching narrow would also
+// match broad, i.e. none of broad's constraints (where set) disagree
+// with narrow's.
+func jwtSourceCovers(broad, narrow *IntentionJWTSource) bool {
+	if broad == nil || narrow == nil {
+		return broad == narrow
+	}
+	if broad.Provider != narrow.Provider {
+		return false
+	}
+	if broad.Issuer != "" && broad.Issuer != narrow.Issuer {
+		return false
+	}
+	if broad.SubjectRegex != "" && broad.SubjectRegex != narrow.SubjectRegex {
+		return false
+	}
+	if len(broad.Audiences) > 0 {
+		if len(narrow.Audiences) == 0 {
+			return false
+		}
+		allowed := make(map[string]bool, len(broad.Audiences))
+		for _, aud := range broad.Audiences {
+			allowed[aud] = true
+		}
+		for _, aud := range narrow.Audiences {
+			if !allowed[aud] {
+				return false
+			}
+		}
+	}
+	for _, bc := range broad.Claims {
+		found := false
+		for _, nc := range narrow.Claims {
+			if equalClaimPaths(bc.Path, nc.Path) && bc.Value == nc.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// equalClaimPaths returns true if a and b name the same nested claim.
+func equalClaimPaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IntentionConflict describes a pair of intentions identified by
+// DetectConflicts as being in conflict.
+type IntentionConflict struct {
+	// A and B are the IDs of the conflicting intentions, in the order
+	// they appeared in the input to DetectConflicts.
+	A, B string
+
+	// Reason explains why A and B are considered to conflict.
+	Reason ConflictReason
+}
+
+// DetectConflicts scans ixns for every pair flagged by ConflictsWith.
+func DetectConflicts(ixns Intentions) []IntentionConflict {
+	var conflicts []IntentionConflict
+
+	for i := 0; i < len(ixns); i++ {
+		for j := i + 1; j < len(ixns); j++ {
+			if ok, reason := ixns[i].ConflictsWith(ixns[j]); ok {
+				conflicts = append(conflicts, IntentionConflict{
+					A:      ixns[i].ID,
+					B:      ixns[j].ID,
+					Reason: reason,
+				})
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// IntentionConflictsRequest is used to request conflict detection across
+// a datacenter's set of intentions.
+type IntentionConflictsRequest struct {
+	// Datacenter is the target this request is intended for.
+	Datacenter string
+
+	// Options for queries
+	QueryOptions
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (q *IntentionConflictsRequest) RequestDatacenter() string {
+	return q.Datacenter
+}
+
+// IndexedIntentionConflicts represents the list of conflicts found by an
+// IntentionConflictsRequest.
+type IndexedIntentionConflicts struct {
+	Conflicts []IntentionConflict
+	QueryMeta
 }
@@ -0,0 +1,108 @@
+package structs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func consulIxn(id, srcNS, srcName, dstNS, dstName string, action IntentionAction, precedence int) *Intention {
+	ixn := &Intention{
+		ID:              id,
+		SourceType:      IntentionSourceConsul,
+		SourceNS:        srcNS,
+		SourceName:      srcName,
+		DestinationNS:   dstNS,
+		DestinationName: dstName,
+		Action:          action,
+	}
+	if precedence == 0 {
+		ixn.UpdatePrecedence()
+	} else {
+		ixn.Precedence = precedence
+	}
+	return ixn
+}
+
+func TestIntention_ConflictsWith_consulSources(t *testing.T) {
+	t.Run("same action never conflicts", func(t *testing.T) {
+		a := consulIxn("a", "default", "web", "default", "api", IntentionActionAllow, 9)
+		b := consulIxn("b", "default", "web", "default", "api", IntentionActionAllow, 9)
+
+		ok, _ := a.ConflictsWith(b)
+		require.False(t, ok)
+	})
+
+	t.Run("disjoint exact sources never conflict", func(t *testing.T) {
+		a := consulIxn("a", "default", "web", "default", "api", IntentionActionAllow, 9)
+		b := consulIxn("b", "default", "worker", "default", "api", IntentionActionDeny, 9)
+
+		ok, _ := a.ConflictsWith(b)
+		require.False(t, ok)
+	})
+
+	t.Run("equal precedence overlapping wildcard source conflicts", func(t *testing.T) {
+		a := consulIxn("a", WildcardSpecifier, WildcardSpecifier, "default", "api", IntentionActionAllow, 9)
+		b := consulIxn("b", "default", "web", "default", "api", IntentionActionDeny, 9)
+
+		ok, reason := a.ConflictsWith(b)
+		require.True(t, ok)
+		require.Equal(t, ConflictReasonOverlappingAction, reason)
+	})
+
+	t.Run("disjoint destinations never conflict even with equal precedence", func(t *testing.T) {
+		a := consulIxn("a", WildcardSpecifier, WildcardSpecifier, "default", "api", IntentionActionAllow, 9)
+		b := consulIxn("b", WildcardSpecifier, WildcardSpecifier, "default", "worker", IntentionActionDeny, 9)
+
+		ok, _ := a.ConflictsWith(b)
+		require.False(t, ok)
+	})
+
+	t.Run("broader higher-precedence rule shadows a narrower opposite rule", func(t *testing.T) {
+		broad := consulIxn("broad", WildcardSpecifier, WildcardSpecifier, "default", "api", IntentionActionDeny, 9)
+		narrow := consulIxn("narrow", "default", "web", "default", "api", IntentionActionAllow, 6)
+
+		ok, reason := narrow.ConflictsWith(broad)
+		require.True(t, ok)
+		require.Equal(t, ConflictReasonShadowed, reason)
+	})
+
+	t.Run("higher-precedence rule that is narrower than the lower one does not shadow it", func(t *testing.T) {
+		narrow := consulIxn("narrow", "default", "web", "default", "api", IntentionActionAllow, 9)
+		broad := consulIxn("broad", WildcardSpecifier, WildcardSpecifier, "default", "api", IntentionActionDeny, 6)
+
+		// narrow has the higher precedence but doesn't cover broad's
+		// wildcard source, so neither fully shadows the other.
+		ok, _ := narrow.ConflictsWith(broad)
+		require.False(t, ok)
+	})
+}
+
+func TestDetectConflicts(t *testing.T) {
+	t.Run("no conflicts among disjoint intentions", func(t *testing.T) {
+		ixns := Intentions{
+			consulIxn("a", "default", "web", "default", "api", IntentionActionAllow, 9),
+			consulIxn("b", "default", "worker", "default", "api", IntentionActionDeny, 9),
+		}
+		require.Empty(t, DetectConflicts(ixns))
+	})
+
+	t.Run("finds every conflicting pair", func(t *testing.T) {
+		ixns := Intentions{
+			consulIxn("a", WildcardSpecifier, WildcardSpecifier, "default", "api", IntentionActionAllow, 9),
+			consulIxn("b", "default", "web", "default", "api", IntentionActionDeny, 9),
+			consulIxn("c", "default", "worker", "default", "api", IntentionActionDeny, 9),
+		}
+
+		conflicts := DetectConflicts(ixns)
+		require.Len(t, conflicts, 2)
+
+		got := map[string]bool{}
+		for _, c := range conflicts {
+			got[c.A+"-"+c.B] = true
+			require.Equal(t, ConflictReasonOverlappingAction, c.Reason)
+		}
+		require.True(t, got["a-b"])
+		require.True(t, got["a-c"])
+	})
+}
@@ -0,0 +1,107 @@
+package structs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, s)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestParseCronField(t *testing.T) {
+	cases := []struct {
+		name      string
+		field     string
+		min, max  int
+		wantErr   bool
+		wantTrue  []int
+		wantFalse []int
+	}{
+		{
+			name: "wildcard matches everything in range",
+			field: "*", min: 0, max: 5,
+			wantTrue: []int{0, 3, 5},
+		},
+		{
+			name: "single value",
+			field: "3", min: 0, max: 5,
+			wantTrue:  []int{3},
+			wantFalse: []int{0, 4},
+		},
+		{
+			name: "list",
+			field: "1,3,5", min: 0, max: 5,
+			wantTrue:  []int{1, 3, 5},
+			wantFalse: []int{0, 2, 4},
+		},
+		{
+			name: "range",
+			field: "1-3", min: 0, max: 5,
+			wantTrue:  []int{1, 2, 3},
+			wantFalse: []int{0, 4},
+		},
+		{
+			name: "wildcard step",
+			field: "*/15", min: 0, max: 59,
+			wantTrue:  []int{0, 15, 30, 45},
+			wantFalse: []int{1, 16, 44},
+		},
+		{
+			name: "ranged step",
+			field: "0-30/10", min: 0, max: 59,
+			wantTrue:  []int{0, 10, 20, 30},
+			wantFalse: []int{5, 40},
+		},
+		{name: "out of range value is invalid", field: "99", min: 0, max: 59, wantErr: true},
+		{name: "inverted range is invalid", field: "5-1", min: 0, max: 59, wantErr: true},
+		{name: "non-numeric value is invalid", field: "abc", min: 0, max: 59, wantErr: true},
+		{name: "zero step is invalid", field: "*/0", min: 0, max: 59, wantErr: true},
+		{name: "negative step is invalid", field: "*/-5", min: 0, max: 59, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			match, err := parseCronField(tc.field, tc.min, tc.max)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, v := range tc.wantTrue {
+				require.Truef(t, match(v), "expected %d to match %q", v, tc.field)
+			}
+			for _, v := range tc.wantFalse {
+				require.Falsef(t, match(v), "expected %d not to match %q", v, tc.field)
+			}
+		})
+	}
+}
+
+func TestParseCronFields(t *testing.T) {
+	t.Run("valid 5-field expression parses", func(t *testing.T) {
+		fields, err := parseCronFields("0 9 * * 1-5")
+		require.NoError(t, err)
+
+		mon9am := mustParseTime(t, "2026-03-02T09:00:00Z") // a Monday
+		require.True(t, fields.matches(mon9am))
+
+		sat9am := mustParseTime(t, "2026-03-07T09:00:00Z") // a Saturday
+		require.False(t, fields.matches(sat9am))
+	})
+
+	t.Run("wrong field count is invalid", func(t *testing.T) {
+		_, err := parseCronFields("0 9 * *")
+		require.Error(t, err)
+	})
+
+	t.Run("invalid field propagates its error", func(t *testing.T) {
+		_, err := parseCronFields("99 9 * * *")
+		require.Error(t, err)
+	})
+}
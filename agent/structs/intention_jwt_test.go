@@ -0,0 +1,164 @@
+package structs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntentionJWTSource_Matches(t *testing.T) {
+	cases := []struct {
+		name   string
+		source IntentionJWTSource
+		claims *IntentionJWTClaims
+		want   bool
+	}{
+		{
+			name:   "no claims never matches",
+			source: IntentionJWTSource{Provider: "okta"},
+			claims: nil,
+			want:   false,
+		},
+		{
+			name:   "bare provider matches anything",
+			source: IntentionJWTSource{Provider: "okta"},
+			claims: &IntentionJWTClaims{Issuer: "https://okta.example.com"},
+			want:   true,
+		},
+		{
+			name:   "issuer hit",
+			source: IntentionJWTSource{Provider: "okta", Issuer: "https://okta.example.com"},
+			claims: &IntentionJWTClaims{Issuer: "https://okta.example.com"},
+			want:   true,
+		},
+		{
+			name:   "issuer miss",
+			source: IntentionJWTSource{Provider: "okta", Issuer: "https://okta.example.com"},
+			claims: &IntentionJWTClaims{Issuer: "https://other.example.com"},
+			want:   false,
+		},
+		{
+			name:   "audience hit",
+			source: IntentionJWTSource{Provider: "okta", Audiences: []string{"web", "mobile"}},
+			claims: &IntentionJWTClaims{Audience: []string{"mobile"}},
+			want:   true,
+		},
+		{
+			name:   "audience miss",
+			source: IntentionJWTSource{Provider: "okta", Audiences: []string{"web"}},
+			claims: &IntentionJWTClaims{Audience: []string{"mobile"}},
+			want:   false,
+		},
+		{
+			name:   "subject regex hit",
+			source: IntentionJWTSource{Provider: "okta", SubjectRegex: "^admin-.*$"},
+			claims: &IntentionJWTClaims{Subject: "admin-jane"},
+			want:   true,
+		},
+		{
+			name:   "subject regex miss",
+			source: IntentionJWTSource{Provider: "okta", SubjectRegex: "^admin-.*$"},
+			claims: &IntentionJWTClaims{Subject: "guest-jane"},
+			want:   false,
+		},
+		{
+			name: "claim assertion hit",
+			source: IntentionJWTSource{
+				Provider: "okta",
+				Claims:   []IntentionJWTClaimAssertion{{Path: []string{"role"}, Value: "admin"}},
+			},
+			claims: &IntentionJWTClaims{Claims: map[string]interface{}{"role": "admin"}},
+			want:   true,
+		},
+		{
+			name: "claim assertion miss",
+			source: IntentionJWTSource{
+				Provider: "okta",
+				Claims:   []IntentionJWTClaimAssertion{{Path: []string{"role"}, Value: "admin"}},
+			},
+			claims: &IntentionJWTClaims{Claims: map[string]interface{}{"role": "guest"}},
+			want:   false,
+		},
+		{
+			name: "nested claim assertion hit",
+			source: IntentionJWTSource{
+				Provider: "okta",
+				Claims:   []IntentionJWTClaimAssertion{{Path: []string{"org", "role"}, Value: "admin"}},
+			},
+			claims: &IntentionJWTClaims{
+				Claims: map[string]interface{}{
+					"org": map[string]interface{}{"role": "admin"},
+				},
+			},
+			want: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.source.Matches(tc.claims))
+		})
+	}
+}
+
+func TestIntention_Validate_jwtSourceExclusivity(t *testing.T) {
+	base := func() *Intention {
+		return &Intention{
+			DestinationNS:   "default",
+			DestinationName: "web",
+			Action:          IntentionActionAllow,
+		}
+	}
+
+	t.Run("consul source cannot also set SourceJWT", func(t *testing.T) {
+		ixn := base()
+		ixn.SourceType = IntentionSourceConsul
+		ixn.SourceNS = "default"
+		ixn.SourceName = "api"
+		ixn.SourceJWT = &IntentionJWTSource{Provider: "okta"}
+
+		require.Error(t, ixn.Validate())
+	})
+
+	t.Run("jwt source cannot also set SourceNS/SourceName", func(t *testing.T) {
+		ixn := base()
+		ixn.SourceType = IntentionSourceJWT
+		ixn.SourceNS = "default"
+		ixn.SourceName = "api"
+		ixn.SourceJWT = &IntentionJWTSource{Provider: "okta"}
+
+		require.Error(t, ixn.Validate())
+	})
+
+	t.Run("jwt source without SourceJWT is invalid", func(t *testing.T) {
+		ixn := base()
+		ixn.SourceType = IntentionSourceJWT
+
+		require.Error(t, ixn.Validate())
+	})
+
+	t.Run("jwt source without provider is invalid", func(t *testing.T) {
+		ixn := base()
+		ixn.SourceType = IntentionSourceJWT
+		ixn.SourceJWT = &IntentionJWTSource{}
+
+		require.Error(t, ixn.Validate())
+	})
+
+	t.Run("well formed jwt source is valid", func(t *testing.T) {
+		ixn := base()
+		ixn.SourceType = IntentionSourceJWT
+		ixn.SourceJWT = &IntentionJWTSource{Provider: "okta"}
+
+		require.NoError(t, ixn.Validate())
+	})
+
+	t.Run("well formed consul source is valid", func(t *testing.T) {
+		ixn := base()
+		ixn.SourceType = IntentionSourceConsul
+		ixn.SourceNS = "default"
+		ixn.SourceName = "api"
+
+		require.NoError(t, ixn.Validate())
+	})
+}
@@ -0,0 +1,271 @@
+package structs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntentionHTTPPermission_Matches(t *testing.T) {
+	cases := []struct {
+		name string
+		perm IntentionHTTPPermission
+		req  IntentionHTTPRequest
+		want bool
+	}{
+		{
+			name: "path exact hit",
+			perm: IntentionHTTPPermission{PathExact: "/v1/health"},
+			req:  IntentionHTTPRequest{Path: "/v1/health"},
+			want: true,
+		},
+		{
+			name: "path exact miss",
+			perm: IntentionHTTPPermission{PathExact: "/v1/health"},
+			req:  IntentionHTTPRequest{Path: "/v1/health/extra"},
+			want: false,
+		},
+		{
+			name: "path prefix hit",
+			perm: IntentionHTTPPermission{PathPrefix: "/v1/"},
+			req:  IntentionHTTPRequest{Path: "/v1/health"},
+			want: true,
+		},
+		{
+			name: "path prefix miss",
+			perm: IntentionHTTPPermission{PathPrefix: "/v1/"},
+			req:  IntentionHTTPRequest{Path: "/v2/health"},
+			want: false,
+		},
+		{
+			name: "path regex hit",
+			perm: IntentionHTTPPermission{PathRegex: "^/v[12]/health$"},
+			req:  IntentionHTTPRequest{Path: "/v2/health"},
+			want: true,
+		},
+		{
+			name: "path regex miss",
+			perm: IntentionHTTPPermission{PathRegex: "^/v[12]/health$"},
+			req:  IntentionHTTPRequest{Path: "/v3/health"},
+			want: false,
+		},
+		{
+			name: "no path matcher matches any path",
+			perm: IntentionHTTPPermission{Methods: []string{"GET"}},
+			req:  IntentionHTTPRequest{Method: "GET", Path: "/anything"},
+			want: true,
+		},
+		{
+			name: "method matches case-insensitively",
+			perm: IntentionHTTPPermission{Methods: []string{"get"}},
+			req:  IntentionHTTPRequest{Method: "GET", Path: "/v1/health"},
+			want: true,
+		},
+		{
+			name: "method miss",
+			perm: IntentionHTTPPermission{Methods: []string{"GET", "HEAD"}},
+			req:  IntentionHTTPRequest{Method: "POST", Path: "/v1/health"},
+			want: false,
+		},
+		{
+			name: "header must match too",
+			perm: IntentionHTTPPermission{
+				PathExact: "/v1/health",
+				Header:    []IntentionHTTPHeaderPermission{{Name: "x-env", Exact: "prod"}},
+			},
+			req: IntentionHTTPRequest{
+				Path:   "/v1/health",
+				Header: map[string][]string{"x-env": {"staging"}},
+			},
+			want: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.perm.Matches(&tc.req))
+		})
+	}
+}
+
+func TestIntentionHTTPHeaderPermission_matches_present(t *testing.T) {
+	cases := []struct {
+		name   string
+		hdr    IntentionHTTPHeaderPermission
+		header map[string][]string
+		want   bool
+	}{
+		{
+			name:   "present matches any value",
+			hdr:    IntentionHTTPHeaderPermission{Name: "x-foo", Present: true},
+			header: map[string][]string{"x-foo": {"anything"}},
+			want:   true,
+		},
+		{
+			name:   "exact hit",
+			hdr:    IntentionHTTPHeaderPermission{Name: "x-foo", Exact: "bar"},
+			header: map[string][]string{"x-foo": {"bar"}},
+			want:   true,
+		},
+		{
+			name:   "exact miss",
+			hdr:    IntentionHTTPHeaderPermission{Name: "x-foo", Exact: "bar"},
+			header: map[string][]string{"x-foo": {"baz"}},
+			want:   false,
+		},
+		{
+			name:   "prefix hit",
+			hdr:    IntentionHTTPHeaderPermission{Name: "x-foo", Prefix: "ba"},
+			header: map[string][]string{"x-foo": {"baz"}},
+			want:   true,
+		},
+		{
+			name:   "suffix hit",
+			hdr:    IntentionHTTPHeaderPermission{Name: "x-foo", Suffix: "az"},
+			header: map[string][]string{"x-foo": {"baz"}},
+			want:   true,
+		},
+		{
+			name:   "regex hit",
+			hdr:    IntentionHTTPHeaderPermission{Name: "x-foo", Regex: "^ba.$"},
+			header: map[string][]string{"x-foo": {"baz"}},
+			want:   true,
+		},
+		{
+			name:   "regex miss",
+			hdr:    IntentionHTTPHeaderPermission{Name: "x-foo", Regex: "^ba.$"},
+			header: map[string][]string{"x-foo": {"bazz"}},
+			want:   false,
+		},
+		{
+			name:   "exact inverted on a hit is a miss",
+			hdr:    IntentionHTTPHeaderPermission{Name: "x-foo", Exact: "bar", Invert: true},
+			header: map[string][]string{"x-foo": {"bar"}},
+			want:   false,
+		},
+		{
+			name:   "multi-value header matches if any value matches",
+			hdr:    IntentionHTTPHeaderPermission{Name: "x-foo", Exact: "bar"},
+			header: map[string][]string{"x-foo": {"baz", "bar"}},
+			want:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.hdr.matches(tc.header))
+		})
+	}
+}
+
+func TestIntentionHTTPHeaderPermission_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		hdr     IntentionHTTPHeaderPermission
+		wantErr bool
+	}{
+		{"present only is valid", IntentionHTTPHeaderPermission{Name: "x-foo", Present: true}, false},
+		{"exact only is valid", IntentionHTTPHeaderPermission{Name: "x-foo", Exact: "bar"}, false},
+		{"missing name is invalid", IntentionHTTPHeaderPermission{Exact: "bar"}, true},
+		{"no criteria is invalid", IntentionHTTPHeaderPermission{Name: "x-foo"}, true},
+		{
+			"multiple criteria is invalid",
+			IntentionHTTPHeaderPermission{Name: "x-foo", Exact: "bar", Prefix: "b"},
+			true,
+		},
+		{
+			"invalid regex is invalid",
+			IntentionHTTPHeaderPermission{Name: "x-foo", Regex: "("},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.hdr.Validate()
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIntentionHTTPPermission_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		perm    IntentionHTTPPermission
+		wantErr bool
+	}{
+		{"empty is valid", IntentionHTTPPermission{}, false},
+		{"single path matcher is valid", IntentionHTTPPermission{PathExact: "/v1"}, false},
+		{
+			"multiple path matchers is invalid",
+			IntentionHTTPPermission{PathExact: "/v1", PathPrefix: "/v1"},
+			true,
+		},
+		{
+			"path not starting with / is invalid",
+			IntentionHTTPPermission{PathExact: "v1"},
+			true,
+		},
+		{
+			"invalid path regex is invalid",
+			IntentionHTTPPermission{PathRegex: "("},
+			true,
+		},
+		{
+			"invalid method is invalid",
+			IntentionHTTPPermission{Methods: []string{"FETCH"}},
+			true,
+		},
+		{
+			"valid method is valid",
+			IntentionHTTPPermission{Methods: []string{"GET", "POST"}},
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.perm.Validate()
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIntentionPermission_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		perm    IntentionPermission
+		wantErr bool
+	}{
+		{"allow with no HTTP is valid", IntentionPermission{Action: IntentionActionAllow}, false},
+		{"deny is valid", IntentionPermission{Action: IntentionActionDeny}, false},
+		{"bad action is invalid", IntentionPermission{Action: "bogus"}, true},
+		{
+			"invalid HTTP matcher is invalid",
+			IntentionPermission{
+				Action: IntentionActionAllow,
+				HTTP:   &IntentionHTTPPermission{PathExact: "/v1", PathPrefix: "/v1"},
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.perm.Validate()
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
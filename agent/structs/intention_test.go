@@ -0,0 +1,161 @@
+package structs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntentionHTTPHeaderPermission_matches_absent(t *testing.T) {
+	cases := []struct {
+		name   string
+		hdr    IntentionHTTPHeaderPermission
+		header map[string][]string
+		want   bool
+	}{
+		{
+			name: "present required, header absent",
+			hdr:  IntentionHTTPHeaderPermission{Name: "x-foo", Present: true},
+			want: false,
+		},
+		{
+			name: "present required and inverted, header absent",
+			hdr:  IntentionHTTPHeaderPermission{Name: "x-foo", Present: true, Invert: true},
+			want: true,
+		},
+		{
+			name: "exact required and inverted, header absent",
+			hdr:  IntentionHTTPHeaderPermission{Name: "x-foo", Exact: "bar", Invert: true},
+			want: true,
+		},
+		{
+			name: "exact required, header absent",
+			hdr:  IntentionHTTPHeaderPermission{Name: "x-foo", Exact: "bar"},
+			want: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.hdr.matches(map[string][]string{}))
+		})
+	}
+}
+
+func TestIntention_UpdatePrecedence_jwtSource(t *testing.T) {
+	base := func() *Intention {
+		return &Intention{
+			SourceType:      IntentionSourceJWT,
+			DestinationNS:   "default",
+			DestinationName: "web",
+		}
+	}
+
+	t.Run("bare provider is as general as a wildcarded consul source", func(t *testing.T) {
+		ixn := base()
+		ixn.SourceJWT = &IntentionJWTSource{Provider: "okta"}
+		ixn.UpdatePrecedence()
+
+		consulWildcard := &Intention{
+			SourceType:      IntentionSourceConsul,
+			SourceNS:        WildcardSpecifier,
+			SourceName:      WildcardSpecifier,
+			DestinationNS:   "default",
+			DestinationName: "web",
+		}
+		consulWildcard.UpdatePrecedence()
+
+		require.Equal(t, consulWildcard.Precedence, ixn.Precedence)
+	})
+
+	t.Run("subject regex narrows precedence above a bare provider", func(t *testing.T) {
+		bare := base()
+		bare.SourceJWT = &IntentionJWTSource{Provider: "okta"}
+		bare.UpdatePrecedence()
+
+		narrow := base()
+		narrow.SourceJWT = &IntentionJWTSource{Provider: "okta", SubjectRegex: "^admin-.*$"}
+		narrow.UpdatePrecedence()
+
+		require.Greater(t, narrow.Precedence, bare.Precedence)
+	})
+}
+
+func TestIntentionSchedule_ActiveAt_longWindow(t *testing.T) {
+	sched := &IntentionSchedule{
+		Cron:            "0 0 1 * *", // first of every month, at midnight
+		DurationMinutes: 14 * 24 * 60,
+		TimeZone:        "UTC",
+	}
+	require.NoError(t, sched.Validate())
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.True(t, sched.ActiveAt(start))
+	require.True(t, sched.ActiveAt(start.Add(10*24*time.Hour)))
+	require.False(t, sched.ActiveAt(start.Add(15*24*time.Hour)))
+}
+
+func TestIntentionSchedule_Validate_rejectsOverlongDuration(t *testing.T) {
+	sched := &IntentionSchedule{
+		Cron:            "0 0 * * *",
+		DurationMinutes: maxScheduleLookback + 1,
+	}
+	require.Error(t, sched.Validate())
+}
+
+func TestIntention_ConflictsWith_jwtSources(t *testing.T) {
+	base := func(provider string, action IntentionAction) *Intention {
+		return &Intention{
+			SourceType:      IntentionSourceJWT,
+			SourceJWT:       &IntentionJWTSource{Provider: provider},
+			DestinationNS:   "default",
+			DestinationName: "web",
+			Action:          action,
+			Precedence:      9,
+		}
+	}
+
+	t.Run("different providers never conflict", func(t *testing.T) {
+		a := base("okta", IntentionActionAllow)
+		b := base("partner-idp", IntentionActionDeny)
+
+		ok, _ := a.ConflictsWith(b)
+		require.False(t, ok)
+	})
+
+	t.Run("same provider with no distinguishing claims conflicts", func(t *testing.T) {
+		a := base("okta", IntentionActionAllow)
+		b := base("okta", IntentionActionDeny)
+
+		ok, reason := a.ConflictsWith(b)
+		require.True(t, ok)
+		require.Equal(t, ConflictReasonOverlappingAction, reason)
+	})
+
+	t.Run("same provider with contradictory claim assertions don't conflict", func(t *testing.T) {
+		a := base("okta", IntentionActionAllow)
+		a.SourceJWT.Claims = []IntentionJWTClaimAssertion{{Path: []string{"sub"}, Value: "admin"}}
+		b := base("okta", IntentionActionDeny)
+		b.SourceJWT.Claims = []IntentionJWTClaimAssertion{{Path: []string{"sub"}, Value: "guest"}}
+
+		ok, _ := a.ConflictsWith(b)
+		require.False(t, ok)
+	})
+
+	t.Run("consul and jwt sources never conflict", func(t *testing.T) {
+		a := base("okta", IntentionActionAllow)
+		b := &Intention{
+			SourceType:      IntentionSourceConsul,
+			SourceNS:        WildcardSpecifier,
+			SourceName:      WildcardSpecifier,
+			DestinationNS:   "default",
+			DestinationName: "web",
+			Action:          IntentionActionDeny,
+			Precedence:      9,
+		}
+
+		ok, _ := a.ConflictsWith(b)
+		require.False(t, ok)
+	})
+}